@@ -2,14 +2,22 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"strings"
 
 	"github.com/jessevdk/go-flags"
 	log "github.com/sirupsen/logrus"
 
+	"golang.org/x/crypto/openpgp/packet"
+
+	"github.com/leesdolphin/dl-verify/distsign"
 	"github.com/leesdolphin/dl-verify/gpg"
 	"github.com/leesdolphin/dl-verify/lib"
 )
@@ -20,8 +28,51 @@ type Config struct {
 	Verbose bool   `short:"v" long:"verbose" description:"Verbose output mode"`
 	OutDir  string `short:"o" long:"output-dir" description:"File output directory"`
 
-	Checksums dlverify.ChecksumConfig `group:"Checksums Verification"`
-	// GPG       gpg.SignatureConfig     `group:"GPG Signature Verification"`
+	Checksums       dlverify.ChecksumConfig   `group:"Checksums Verification"`
+	RemoteChecksums gpg.RemoteChecksumsConfig `group:"Remote Checksums File Verification"`
+	GPG             gpg.SignatureConfig       `group:"GPG Signature Verification"`
+	Distsign        distsign.Config           `group:"Signed Manifest Verification"`
+}
+
+// resolveSignaturePublicKey finds the public key a GPG signature should be
+// verified against, either from local/remote key material or by fetching it
+// from a keyserver using the configured KeyID.
+func resolveSignaturePublicKey(ctx context.Context, config gpg.SignatureConfig, tempFolder string) (*packet.PublicKey, error) {
+	keyPath := config.PublicKeyPath
+	if config.PublicKeyURL != "" {
+		var err error
+		keyPath, err = dlverify.NewDownloader().Download(ctx, tempFolder, config.PublicKeyURL)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if keyPath != "" {
+		key, err := gpg.ReadArmoredPublicKeyFile(keyPath)
+		if err != nil || config.KeyID == "" {
+			return key, err
+		}
+		keyID, err := gpg.NewKeyID(config.KeyID)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasSuffix(fmt.Sprintf("%X", key.Fingerprint), string(*keyID)) {
+			return nil, fmt.Errorf("public key does not match GPG key ID %s", config.KeyID)
+		}
+		return key, nil
+	}
+	if config.KeyID == "" {
+		return nil, errors.New("no public key, public key URL or GPG key ID given to verify the signature against")
+	}
+	keyID, err := gpg.NewKeyID(config.KeyID)
+	if err != nil {
+		return nil, err
+	}
+	ksi := gpg.DefaultKeyServerInformation()
+	var emails []string
+	if config.Email != "" {
+		emails = append(emails, config.Email)
+	}
+	return ksi.DownloadKey(ctx, keyID, nil, emails...)
 }
 
 // ConfigureLogging sets the logger's settings to those specified in Config
@@ -55,13 +106,6 @@ func writeOutFile(path string) error {
 }
 
 func main() {
-	{
-		key, _ := gpg.NewKeyID("595E85A6B1B4779EA4DAAEC70B588DFF0527A9B7")
-		ksi := gpg.DefaultKeyServerInformation()
-		k, err := ksi.DownloadKey(context.Background(), key, nil)
-		fmt.Printf("%#+v\n\n%#+v", k, err)
-	}
-
 	// Parse command line arguments
 	args := Config{}
 	_, err := flags.Parse(&args)
@@ -95,30 +139,98 @@ func main() {
 		os.Exit(4)
 	}
 	defer os.RemoveAll(tempFolder)
-	path, err := dlverify.DownloadToTemporaryFile(tempFolder, args.URL)
 
-	// This will be set to true if *any* form of verification has been done.
-	isVerified := false
+	// Ctrl-C cancels an in-progress download instead of leaving it to run to
+	// completion.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	go func() {
+		if _, ok := <-interrupt; ok {
+			log.Warn("Received interrupt, cancelling download")
+			cancel()
+		}
+	}()
 
-	verification, err := args.Checksums.VerifyFileChecksums(path)
-	if err != nil {
-		os.Exit(4)
+	if args.RemoteChecksums.Enabled() {
+		checksum, err := args.RemoteChecksums.ResolveChecksum(ctx, tempFolder, path.Base(args.URL))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to resolve remote checksums file.\n%s\n", err)
+			os.Exit(4)
+		}
+		args.Checksums.Sha256 = checksum
 	}
+
+	var localPath string
+	var checksumAuth *dlverify.AuthenticationResult
+	if args.Distsign.Enabled() {
+		localPath, checksumAuth, err = distsign.NewDownloader(args.Distsign).Download(ctx, tempFolder, args.URL)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Signed manifest verification failed.\n%s\n", err)
+			os.Exit(1)
+		}
+	} else {
+		hashWriter, finishHash := args.Checksums.NewMultiHasher()
+		downloader := dlverify.NewDownloader()
+		downloader.Extra = []io.Writer{hashWriter}
+		if args.Verbose {
+			downloader.Progress = func(p dlverify.ProgressUpdate) {
+				log.WithFields(log.Fields{
+					"bytesSoFar": p.BytesSoFar, "totalBytes": p.TotalBytes,
+				}).Debug("Download progress")
+			}
+		}
+		localPath, err = downloader.Download(ctx, tempFolder, args.URL)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Download failed.\n%s\n", err)
+			os.Exit(4)
+		}
+		checksumAuth = dlverify.NewAuthenticationResultFromChecksum(finishHash())
+	}
+	signal.Stop(interrupt)
+	close(interrupt)
+
 	log.WithFields(log.Fields{
-		"valid":   verification.Valid,
-		"invalid": verification.Invalid,
+		"valid":   checksumAuth.Checksum.Valid,
+		"invalid": checksumAuth.Checksum.Invalid,
 	}).Info("Checksum Verification Results.")
-	if verification.IsInvalid() {
+	if checksumAuth.Checksum.IsInvalid() {
 		fmt.Fprintf(
 			os.Stderr,
 			"Checksum verification failed.\n%s.\n",
-			verification.ToMessage(),
+			checksumAuth.Checksum.ToMessage(),
 		)
 		os.Exit(1)
 	}
-	isVerified = isVerified || (!verification.IsNoOp())
 
-	if !isVerified {
+	gpgAuth := &dlverify.AuthenticationResult{}
+	if args.GPG.Enabled() {
+		sigPath := args.GPG.SignaturePath
+		if args.GPG.SignatureURL != "" {
+			sigPath, err = dlverify.NewDownloader().Download(ctx, tempFolder, args.GPG.SignatureURL)
+			if err != nil {
+				os.Exit(4)
+			}
+		}
+		key, err := resolveSignaturePublicKey(ctx, args.GPG, tempFolder)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to resolve GPG public key.\n%s\n", err)
+			os.Exit(4)
+		}
+		gpgAuth, err = gpg.VerifyDetachedSignature(localPath, sigPath, key)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to verify GPG signature.\n%s\n", err)
+			os.Exit(4)
+		}
+		if !gpgAuth.IsAuthenticated() {
+			fmt.Fprintln(os.Stderr, "GPG signature verification failed.")
+			os.Exit(1)
+		}
+	}
+
+	auth := dlverify.MergeAuthenticationResults(checksumAuth, gpgAuth)
+	if !auth.IsAuthenticated() {
 		// No verification!
 		fmt.Fprintln(
 			os.Stderr,
@@ -126,9 +238,10 @@ func main() {
 		)
 		os.Exit(1)
 	}
+	fmt.Fprintf(os.Stderr, "Downloaded %s (%s)\n", filepath.Base(localPath), auth)
 	// Completed verification steps. Now to dump to StdOut
 
-	err = writeOutFile(path)
+	err = writeOutFile(localPath)
 	if err != nil {
 		fmt.Fprintln(
 			os.Stderr,
@@ -0,0 +1,174 @@
+// Package distsign treats a release as a signed manifest of artifacts,
+// rather than verifying individual downloads against flags passed on the
+// command line. A manifest lists every file in a release along with its
+// size and checksums; the manifest itself is signed by a pinned root key,
+// so trusting that one key is enough to verify any artifact in the release.
+package distsign
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/leesdolphin/dl-verify/gpg"
+	dlverify "github.com/leesdolphin/dl-verify/lib"
+)
+
+// ErrManifestSignatureInvalid is returned when the manifest's signature does
+// not verify against the configured root key.
+var ErrManifestSignatureInvalid = errors.New("manifest signature did not verify against the root key")
+
+// ErrSizeMismatch is returned when a downloaded artifact's size does not
+// match the size pinned for it in the signed manifest.
+type ErrSizeMismatch struct {
+	Filename     string
+	ExpectedSize int64
+	ActualSize   int64
+}
+
+func (e ErrSizeMismatch) Error() string {
+	return fmt.Sprintf(
+		"%s: manifest expects %d bytes, downloaded %d bytes",
+		e.Filename, e.ExpectedSize, e.ActualSize,
+	)
+}
+
+// ManifestEntry describes a single artifact published as part of a release.
+type ManifestEntry struct {
+	Filename string `json:"filename"`
+	Size     int64  `json:"size"`
+	Sha256   string `json:"sha256"`
+	Sha512   string `json:"sha512"`
+}
+
+// Manifest is a signed list of the artifacts that make up a release.
+type Manifest struct {
+	Entries []ManifestEntry `json:"entries"`
+}
+
+// Find returns the entry for filename, if the manifest lists one.
+func (m Manifest) Find(filename string) (*ManifestEntry, bool) {
+	for _, entry := range m.Entries {
+		if entry.Filename == filename {
+			return &entry, true
+		}
+	}
+	return nil, false
+}
+
+// Config defines arguments for verifying a download against a signed
+// manifest, rather than individual `--sha256`/`--sha512` flags.
+type Config struct {
+	ManifestURL    string `long:"manifest-url"     description:"URL to the signed manifest listing this release's artifacts"`
+	ManifestSigURL string `long:"manifest-sig-url" description:"URL to the detached GPG signature of the manifest"`
+	RootKeyPath    string `long:"root-key"         description:"Path to the armored public key the manifest signature must be signed by"`
+}
+
+// Enabled returns true when enough configuration has been given to verify a
+// download against a signed manifest.
+func (config Config) Enabled() bool {
+	return config.ManifestURL != "" && config.ManifestSigURL != ""
+}
+
+// Downloader fetches a single artifact and verifies its filename and hashes
+// against a signed manifest covering a whole release.
+type Downloader struct {
+	Config Config
+}
+
+// NewDownloader creates a Downloader for the given Config.
+func NewDownloader(config Config) *Downloader {
+	return &Downloader{Config: config}
+}
+
+// Download fetches url into folder, verifying it against the entry in the
+// signed manifest pinned by d.Config. The manifest, its signature and the
+// artifact itself are all fetched through a dlverify.Downloader, so ctx
+// cancellation, retries and resume apply to every request this makes, not
+// just the final artifact.
+func (d *Downloader) Download(ctx context.Context, folder, url string) (string, *dlverify.AuthenticationResult, error) {
+	downloader := dlverify.NewDownloader()
+
+	manifestPath, err := downloader.Download(ctx, folder, d.Config.ManifestURL)
+	if err != nil {
+		return "", nil, err
+	}
+	sigPath, err := downloader.Download(ctx, folder, d.Config.ManifestSigURL)
+	if err != nil {
+		return "", nil, err
+	}
+	key, err := gpg.ReadArmoredPublicKeyFile(d.Config.RootKeyPath)
+	if err != nil {
+		return "", nil, err
+	}
+	sigResult, err := gpg.VerifyDetachedSignature(manifestPath, sigPath, key)
+	if err != nil {
+		return "", nil, err
+	} else if !sigResult.IsAuthenticated() {
+		return "", nil, ErrManifestSignatureInvalid
+	}
+
+	manifest, err := readManifest(manifestPath)
+	if err != nil {
+		return "", nil, err
+	}
+
+	filename := path.Base(url)
+	entry, ok := manifest.Find(filename)
+	if !ok {
+		return "", nil, fmt.Errorf("manifest does not contain an entry for %q", filename)
+	}
+
+	filePath, err := downloader.Download(ctx, folder, url)
+	if err != nil {
+		return "", nil, err
+	}
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return "", nil, err
+	}
+	if info.Size() != entry.Size {
+		return "", nil, ErrSizeMismatch{
+			Filename:     filename,
+			ExpectedSize: entry.Size,
+			ActualSize:   info.Size(),
+		}
+	}
+	checksums := dlverify.ChecksumConfig{Sha256: entry.Sha256, Sha512: entry.Sha512}
+	result, err := checksums.VerifyFileChecksums(filePath)
+	if err != nil {
+		return "", nil, err
+	}
+	if result.IsAuthenticated() {
+		// These hashes came from a manifest we've just verified was signed
+		// by the pinned root key, so they're worth more than an arbitrary
+		// user-supplied checksum.
+		result.Trust = dlverify.TrustLevelMatchingOfficialHashes
+	}
+	log.WithFields(log.Fields{
+		"url": url, "filename": filename,
+	}).Info("Verified download against signed manifest")
+	return filePath, result, nil
+}
+
+func readManifest(manifestPath string) (*Manifest, error) {
+	file, err := os.Open(manifestPath)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err": err, "path": manifestPath,
+		}).Error("Failed to Open path")
+		return nil, err
+	}
+	defer file.Close()
+
+	manifest := new(Manifest)
+	if err := json.NewDecoder(file).Decode(manifest); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
@@ -71,6 +71,12 @@ func NewCleanedKeyID(originalKeyID string, minLengthForSecurity KeyLength) (*Key
 		return r
 	}, originalKeyID)
 	keyID = strings.ToUpper(keyID)
+	if len(keyID) < 2 {
+		return nil, ErrGpgKeyInvalid{
+			Key:           keyID,
+			InvalidReason: "Key is too short",
+		}
+	}
 	if keyID[0:2] == "0X" {
 		// Remove the prefixing '0X' if it is present.
 		keyID = keyID[2:]
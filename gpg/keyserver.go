@@ -2,11 +2,17 @@ package gpg
 
 import (
 	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
 	"errors"
+	"fmt"
 	"math/rand"
 	"mime"
 	"net/http"
 	"net/url"
+	"strings"
 
 	log "github.com/sirupsen/logrus"
 
@@ -37,17 +43,25 @@ type KeyServerInformation struct {
 	UseHTTP    bool
 	UseHTTPS   bool
 	UseHKP     bool
-	UseHkps    bool
-	// FIXME: Actually support HKPS at some point.
+	// UseHKPS fetches keys over HTTPS, additionally pinning the server's
+	// TLS certificate when a fingerprint for it is present in
+	// PinnedCertFingerprints.
+	UseHKPS bool
+	// UseWKD enables Web Key Directory lookups for any email addresses
+	// passed to DownloadKey, tried before the KeyServers pool.
+	UseWKD bool
+	// PinnedCertFingerprints maps a key server hostname to the SHA256
+	// fingerprint (hex encoded) of the TLS certificate it is expected to
+	// present. Hosts not present here fall back to normal system CA
+	// verification.
+	PinnedCertFingerprints map[string]string
 }
 
 func getDefaultKeyServers() []string {
 	return []string{
-		// "keys.gnupg.net",
-		"pgp.mit.edu",
-		// "keyserver.ubuntu.com",
-		// "pgp.net.nz",
-		// "ha.pool.sks-keyservers.net",
+		"keys.openpgp.org",
+		"keyserver.ubuntu.com",
+		"hkps.pool.sks-keyservers.net",
 	}
 }
 
@@ -55,11 +69,13 @@ func getDefaultKeyServers() []string {
 func DefaultKeyServerInformation() KeyServerInformation {
 	return KeyServerInformation{
 		KeyServers: getDefaultKeyServers(),
-		UseHTTPS:   true,
-		// HKP & HTTP will be used as a fallback in the event all HTTPS requests fail.
-		// E.G. no SSL certs.
-		// UseHKP:  true,
-		// UseHTTP: true,
+		UseHKPS:    true,
+		UseWKD:     true,
+		// HKP, plain HTTP & HTTPS are only used as a fallback in
+		// the event every HKPS/WKD request fails. E.G. no SSL certs.
+		// UseHTTPS: true,
+		// UseHKP:   true,
+		// UseHTTP:  true,
 	}
 }
 
@@ -78,9 +94,13 @@ func (ksi *KeyServerInformation) AddDefaultKeyServers() *KeyServerInformation {
 	return ksi
 }
 
-// KeyServerURLs returns the URLs for the key servers as configured.
+// KeyServerURLs returns the URLs for the key servers as configured, in the
+// order they should be tried: HKPS, then HTTPS, then HKP, then HTTP.
 func (ksi *KeyServerInformation) KeyServerURLs() []url.URL {
 	numProtocols := 0
+	if ksi.UseHKPS {
+		numProtocols++
+	}
 	if ksi.UseHTTPS {
 		numProtocols++
 	}
@@ -99,8 +119,16 @@ func (ksi *KeyServerInformation) KeyServerURLs() []url.URL {
 	for src, target := range rand.Perm(numKeyServers) {
 		keyServer := ksi.KeyServers[src]
 		offset := 0
-		// Here we ensure that we attempt HTTPS, then HKP, then HTTP, in that order.
-		// trying all servers on one protocol before moving onto the next.
+		// Here we ensure that we attempt HKPS, then HTTPS, then HKP, then
+		// HTTP, in that order, trying all servers on one protocol before
+		// moving onto the next.
+		if ksi.UseHKPS {
+			outputKeySevers[offset+target] = url.URL{
+				Scheme: "https",
+				Host:   keyServer,
+			}
+			offset += numKeyServers
+		}
 		if ksi.UseHTTPS {
 			outputKeySevers[offset+target] = url.URL{
 				Scheme: "https",
@@ -127,8 +155,48 @@ func (ksi *KeyServerInformation) KeyServerURLs() []url.URL {
 	return outputKeySevers
 }
 
+// clientFor returns the HTTP client to use when talking to serverURL,
+// pinning its TLS certificate when one is configured for that host.
+func (ksi *KeyServerInformation) clientFor(serverURL url.URL, base *http.Client) *http.Client {
+	pin, ok := ksi.PinnedCertFingerprints[serverURL.Hostname()]
+	if !ok || serverURL.Scheme != "https" {
+		return base
+	}
+	return &http.Client{
+		Timeout: base.Timeout,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				// We verify the certificate ourselves, below, against the
+				// pinned fingerprint.
+				InsecureSkipVerify: true,
+				VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+					for _, raw := range rawCerts {
+						sum := sha256.Sum256(raw)
+						if hex.EncodeToString(sum[:]) == pin {
+							return nil
+						}
+					}
+					return fmt.Errorf("no certificate presented by %s matched the pinned fingerprint", serverURL.Host)
+				},
+			},
+		},
+	}
+}
+
+// matchesKeyID reports whether entity's fingerprint ends with key, allowing
+// key to be a short ID, a long ID or a full fingerprint.
+func matchesKeyID(entity *openpgp.Entity, key *KeyID) bool {
+	fingerprint := strings.ToUpper(hex.EncodeToString(entity.PrimaryKey.Fingerprint[:]))
+	return strings.HasSuffix(fingerprint, string(*key))
+}
+
 // DownloadKey downloads and verifies the key using the given key servers.
-func (ksi *KeyServerInformation) DownloadKey(ctx context.Context, key *KeyID, client *http.Client) (*packet.PublicKey, error) {
+// It tries, in order: Web Key Directory for any emails given, then each
+// configured keyserver protocol (HKPS, HTTPS, HKP, HTTP). Errors from
+// individual servers are non-fatal and are only returned, as a MultiError,
+// if every option fails; the exception is a server returning more than one
+// key, which ends the lookup with ErrMultipleKeysReturned.
+func (ksi *KeyServerInformation) DownloadKey(ctx context.Context, key *KeyID, client *http.Client, emails ...string) (*packet.PublicKey, error) {
 	if ctx == nil {
 		panic("context nil")
 	} else if key == nil {
@@ -142,18 +210,32 @@ func (ksi *KeyServerInformation) DownloadKey(ctx context.Context, key *KeyID, cl
 		client = &http.Client{}
 	}
 
+	var errs MultiError
+
+	if ksi.UseWKD {
+		for _, email := range emails {
+			entity, err := wkdLookup(ctx, client, email)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("WKD lookup for %s: %w", email, err))
+				continue
+			}
+			if matchesKeyID(entity, key) {
+				return entity.PrimaryKey, nil
+			}
+			errs = append(errs, fmt.Errorf("WKD key for %s did not match the requested key id", email))
+		}
+	}
+
 	queryParams := url.Values(map[string][]string{
 		"op":      {"get"},
 		"search":  {"0X" + string(*key)},
 		"exact":   {"on"},
 		"options": {"mr"}, // Return the key in a machine readable format(i.e. without surrounding HTML)
 	}).Encode()
-	var entity *openpgp.Entity
-	var nonFatalErr nonFatalError
 	for _, serverURL := range ksi.KeyServerURLs() {
 		serverURL.Path = "/pks/lookup"
 		serverURL.RawQuery = queryParams
-		entity, nonFatalErr, err = downloadKeyFromKeyServer(ctx, serverURL, client)
+		entity, nonFatalErr, err := downloadKeyFromKeyServer(ctx, serverURL, ksi.clientFor(serverURL, client))
 		if err != nil {
 			return nil, err
 		} else if nonFatalErr != nil {
@@ -162,18 +244,21 @@ func (ksi *KeyServerInformation) DownloadKey(ctx context.Context, key *KeyID, cl
 			).WithField(
 				"url", serverURL,
 			).Info("Download failed, trying another server")
+			errs = append(errs, fmt.Errorf("%s: %w", serverURL.Host, nonFatalErr))
+			continue
 		} else if entity != nil {
-			break
+			if matchesKeyID(entity, key) {
+				return entity.PrimaryKey, nil
+			}
+			errs = append(errs, fmt.Errorf("%s: returned key did not match the requested key id", serverURL.Host))
 		}
 	}
-	if nonFatalErr != nil {
-		log.WithError(
-			nonFatalErr,
-		).Error("All servers failed to provide key.")
-		// It's now a fatal error because we couldn't get the key.
-		return nil, nonFatalErr
+
+	if len(errs) > 0 {
+		log.WithError(errs).Error("All servers failed to provide key.")
+		return nil, errs
 	}
-	return entity.PrimaryKey, nil
+	return nil, ErrKeyNotFound
 }
 
 func downloadKeyFromKeyServer(ctx context.Context, url url.URL, client *http.Client) (*openpgp.Entity, nonFatalError, error) {
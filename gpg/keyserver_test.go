@@ -0,0 +1,54 @@
+package gpg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/packet"
+)
+
+func entityWithFingerprint(fingerprint [20]byte) *openpgp.Entity {
+	return &openpgp.Entity{PrimaryKey: &packet.PublicKey{Fingerprint: fingerprint}}
+}
+
+func TestMatchesKeyID(t *testing.T) {
+	var fingerprint [20]byte
+	keySuffix := []byte{0xAB, 0xCD, 0xEF, 0x01, 0x23}
+	copy(fingerprint[len(fingerprint)-len(keySuffix):], keySuffix)
+	entity := entityWithFingerprint(fingerprint)
+
+	t.Run("Matches a short key id that is a suffix of the fingerprint", func(t *testing.T) {
+		key := KeyID("0123")
+		assert.True(t, matchesKeyID(entity, &key))
+	})
+
+	t.Run("Does not match an unrelated key id", func(t *testing.T) {
+		key := KeyID("DEADBEEF")
+		assert.False(t, matchesKeyID(entity, &key))
+	})
+}
+
+func TestKeyServerURLsOrdering(t *testing.T) {
+	t.Run("Tries every configured protocol for every server, HKPS first", func(t *testing.T) {
+		ksi := KeyServerInformation{
+			KeyServers: []string{"a.example", "b.example"},
+			UseHKPS:    true,
+			UseHKP:     true,
+		}
+		urls := ksi.KeyServerURLs()
+		assert.Len(t, urls, 4)
+		for _, u := range urls[:2] {
+			assert.Equal(t, "https", u.Scheme)
+		}
+		for _, u := range urls[2:] {
+			assert.Equal(t, "http", u.Scheme)
+			assert.Contains(t, u.Host, ":11371")
+		}
+	})
+
+	t.Run("Returns no URLs when no protocol is enabled", func(t *testing.T) {
+		ksi := KeyServerInformation{KeyServers: []string{"a.example"}}
+		assert.Empty(t, ksi.KeyServerURLs())
+	})
+}
@@ -0,0 +1,15 @@
+package gpg
+
+import "strings"
+
+// MultiError collects the errors returned by multiple key servers, so a
+// caller can see why every one of them failed instead of only the last.
+type MultiError []error
+
+func (m MultiError) Error() string {
+	messages := make([]string, len(m))
+	for i, err := range m {
+		messages[i] = err.Error()
+	}
+	return "all key servers failed:\n" + strings.Join(messages, "\n")
+}
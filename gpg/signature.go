@@ -0,0 +1,98 @@
+package gpg
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/packet"
+
+	dlverify "github.com/leesdolphin/dl-verify/lib"
+)
+
+// ErrNoPublicKey is returned when signature verification is attempted
+// without a public key to verify against.
+var ErrNoPublicKey = errors.New("no public key given to verify signature against")
+
+// SignatureConfig defines arguments for verifying a detached GPG signature
+// over the downloaded file.
+type SignatureConfig struct {
+	SignatureURL  string `long:"signature-url"   description:"URL to the detached GPG signature for the downloaded file"`
+	SignaturePath string `long:"signature-path"  description:"Local path to the detached GPG signature for the downloaded file"`
+	PublicKeyURL  string `long:"public-key-url"  description:"URL to an armored GPG public key to verify the signature against"`
+	PublicKeyPath string `long:"public-key-path" description:"Local path to an armored GPG public key to verify the signature against"`
+	KeyID         string `long:"gpg-key-id"      description:"Fingerprint (v3 or v4) of the key that must have produced the signature, fetched from a keyserver if no public key is given; shorter KeyIDs are rejected"`
+	Email         string `long:"gpg-key-email"   description:"Email address to try over Web Key Directory before falling back to keyservers"`
+}
+
+// Enabled returns true when enough configuration has been given to attempt
+// signature verification.
+func (config SignatureConfig) Enabled() bool {
+	return config.SignatureURL != "" || config.SignaturePath != ""
+}
+
+// ReadArmoredPublicKeyFile reads a single armored public key from path.
+func ReadArmoredPublicKeyFile(path string) (*packet.PublicKey, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err": err, "path": path,
+		}).Error("Failed to Open path")
+		return nil, err
+	}
+	defer file.Close()
+
+	keyring, err := openpgp.ReadArmoredKeyRing(file)
+	if err != nil {
+		return nil, err
+	} else if len(keyring) == 0 {
+		return nil, ErrKeyNotFound
+	} else if len(keyring) > 1 {
+		return nil, ErrMultipleKeysReturned
+	}
+	return keyring[0].PrimaryKey, nil
+}
+
+// VerifyDetachedSignature checks that sigPath is a valid detached GPG
+// signature of filePath, produced by key.
+func VerifyDetachedSignature(filePath, sigPath string, key *packet.PublicKey) (*dlverify.AuthenticationResult, error) {
+	if key == nil {
+		return nil, ErrNoPublicKey
+	}
+	signedFile, err := os.Open(filePath)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err": err, "path": filePath,
+		}).Error("Failed to Open path")
+		return nil, err
+	}
+	defer signedFile.Close()
+
+	sigFile, err := os.Open(sigPath)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err": err, "path": sigPath,
+		}).Error("Failed to Open path")
+		return nil, err
+	}
+	defer sigFile.Close()
+
+	keyring := openpgp.EntityList{&openpgp.Entity{
+		PrimaryKey: key,
+		Identities: map[string]*openpgp.Identity{},
+	}}
+	signer, err := openpgp.CheckDetachedSignature(keyring, signedFile, sigFile)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err": err, "path": filePath, "sig": sigPath,
+		}).Info("Signature did not verify")
+		return &dlverify.AuthenticationResult{Trust: dlverify.TrustLevelUnverified}, nil
+	}
+	return &dlverify.AuthenticationResult{
+		Trust: dlverify.TrustLevelSignedByKey,
+		KeyID: fmt.Sprintf("%X", signer.PrimaryKey.Fingerprint),
+	}, nil
+}
@@ -0,0 +1,120 @@
+package gpg
+
+import (
+	"context"
+	"crypto/sha1"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/net/context/ctxhttp"
+)
+
+// zBase32Alphabet is the alphabet Web Key Directory uses to encode the
+// SHA1 hash of a mailbox's local part into the lookup URL.
+const zBase32Alphabet = "ybndrfg8ejkmcpqxot1uwisza345h769"
+
+// zBase32Encode encodes data using the z-base32 alphabet.
+func zBase32Encode(data []byte) string {
+	var out strings.Builder
+	var buffer uint32
+	var bufferBits uint
+
+	for _, b := range data {
+		buffer = (buffer << 8) | uint32(b)
+		bufferBits += 8
+		for bufferBits >= 5 {
+			bufferBits -= 5
+			out.WriteByte(zBase32Alphabet[(buffer>>bufferBits)&0x1F])
+		}
+	}
+	if bufferBits > 0 {
+		out.WriteByte(zBase32Alphabet[(buffer<<(5-bufferBits))&0x1F])
+	}
+	return out.String()
+}
+
+// splitEmail splits email into its local part and lowercased domain.
+func splitEmail(email string) (localPart, domain string, err error) {
+	parts := strings.SplitN(email, "@", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("%q is not a valid email address", email)
+	}
+	return parts[0], strings.ToLower(parts[1]), nil
+}
+
+// wkdAdvancedURL builds the Web Key Directory "advanced method" lookup URL
+// for email, as defined by the Web Key Directory draft. The advanced method
+// is served from a dedicated "openpgpkey" subdomain and is tried before the
+// direct method.
+func wkdAdvancedURL(email string) (url.URL, error) {
+	localPart, domain, err := splitEmail(email)
+	if err != nil {
+		return url.URL{}, err
+	}
+	sum := sha1.Sum([]byte(strings.ToLower(localPart)))
+	return url.URL{
+		Scheme:   "https",
+		Host:     "openpgpkey." + domain,
+		Path:     "/.well-known/openpgpkey/" + domain + "/hu/" + zBase32Encode(sum[:]),
+		RawQuery: url.Values{"l": {localPart}}.Encode(),
+	}, nil
+}
+
+// wkdDirectURL builds the Web Key Directory "direct method" lookup URL for
+// email, as defined by the Web Key Directory draft. It is used as a
+// fallback when a domain does not publish a dedicated "openpgpkey"
+// subdomain for the advanced method.
+func wkdDirectURL(email string) (url.URL, error) {
+	localPart, domain, err := splitEmail(email)
+	if err != nil {
+		return url.URL{}, err
+	}
+	sum := sha1.Sum([]byte(strings.ToLower(localPart)))
+	return url.URL{
+		Scheme:   "https",
+		Host:     domain,
+		Path:     "/.well-known/openpgpkey/hu/" + zBase32Encode(sum[:]),
+		RawQuery: url.Values{"l": {localPart}}.Encode(),
+	}, nil
+}
+
+// wkdLookup fetches the public key published for email via Web Key
+// Directory, trying the advanced method first and falling back to the
+// direct method, per the Web Key Directory draft. Unlike the keyserver
+// protocol, WKD serves the key as a raw (non-armored) keyring.
+func wkdLookup(ctx context.Context, client *http.Client, email string) (*openpgp.Entity, error) {
+	advancedURL, err := wkdAdvancedURL(email)
+	if err != nil {
+		return nil, err
+	}
+	if entity, err := fetchWkdKeyring(ctx, client, advancedURL); err == nil {
+		return entity, nil
+	}
+	directURL, err := wkdDirectURL(email)
+	if err != nil {
+		return nil, err
+	}
+	return fetchWkdKeyring(ctx, client, directURL)
+}
+
+// fetchWkdKeyring performs a single WKD lookup against serverURL.
+func fetchWkdKeyring(ctx context.Context, client *http.Client, serverURL url.URL) (*openpgp.Entity, error) {
+	resp, err := ctxhttp.Get(ctx, client, serverURL.String())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, ErrKeyNotFound
+	}
+	keyring, err := openpgp.ReadKeyRing(resp.Body)
+	if err != nil {
+		return nil, err
+	} else if len(keyring) == 0 {
+		return nil, ErrKeyNotFound
+	}
+	return keyring[0], nil
+}
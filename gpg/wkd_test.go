@@ -0,0 +1,58 @@
+package gpg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestZBase32Encode(t *testing.T) {
+	// Values taken from the z-base32 reference test vectors.
+	t.Run("Encodes known test vectors", func(t *testing.T) {
+		assert.Equal(t, "yy", zBase32Encode([]byte{0x00}))
+		assert.Equal(t, "ybndrfg8", zBase32Encode([]byte{0x00, 0x44, 0x32, 0x14, 0xc7}))
+	})
+
+	t.Run("Encodes an empty input to an empty string", func(t *testing.T) {
+		assert.Equal(t, "", zBase32Encode([]byte{}))
+	})
+}
+
+func TestWkdAdvancedURL(t *testing.T) {
+	t.Run("Builds the lookup URL against the openpgpkey subdomain", func(t *testing.T) {
+		u, err := wkdAdvancedURL("Joe.Doe@Example.ORG")
+		assert.Nil(t, err)
+		assert.Equal(t, "https", u.Scheme)
+		assert.Equal(t, "openpgpkey.example.org", u.Host)
+		assert.Contains(t, u.Path, "/.well-known/openpgpkey/example.org/hu/")
+		assert.Equal(t, "Joe.Doe", u.Query().Get("l"))
+	})
+
+	t.Run("Rejects addresses without an @", func(t *testing.T) {
+		_, err := wkdAdvancedURL("not-an-email")
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Rejects addresses with an empty local part or domain", func(t *testing.T) {
+		_, err := wkdAdvancedURL("@example.org")
+		assert.NotNil(t, err)
+		_, err = wkdAdvancedURL("joe@")
+		assert.NotNil(t, err)
+	})
+}
+
+func TestWkdDirectURL(t *testing.T) {
+	t.Run("Builds the lookup URL directly against the domain", func(t *testing.T) {
+		u, err := wkdDirectURL("Joe.Doe@Example.ORG")
+		assert.Nil(t, err)
+		assert.Equal(t, "https", u.Scheme)
+		assert.Equal(t, "example.org", u.Host)
+		assert.Contains(t, u.Path, "/.well-known/openpgpkey/hu/")
+		assert.Equal(t, "Joe.Doe", u.Query().Get("l"))
+	})
+
+	t.Run("Rejects addresses without an @", func(t *testing.T) {
+		_, err := wkdDirectURL("not-an-email")
+		assert.NotNil(t, err)
+	})
+}
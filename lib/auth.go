@@ -0,0 +1,120 @@
+package dlverify
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TrustLevel describes how strongly a downloaded artifact's authenticity
+// has been established, modeled on Terraform's getproviders.PackageAuthenticationResult.
+type TrustLevel int
+
+const (
+	// TrustLevelUnverified means no checksum or signature could be checked.
+	TrustLevelUnverified TrustLevel = iota
+	// TrustLevelVerifiedChecksum means the file matched a checksum the user
+	// supplied, but its origin was not otherwise confirmed.
+	TrustLevelVerifiedChecksum
+	// TrustLevelSignedByKey means the file was matched to a GPG signature
+	// produced by a known key.
+	TrustLevelSignedByKey
+	// TrustLevelMatchingOfficialHashes means the file matched checksums that
+	// were themselves retrieved from a signed, pinned source, such as a
+	// verified distsign manifest.
+	TrustLevelMatchingOfficialHashes
+)
+
+// String renders the trust level the way it should appear in user-facing
+// messages.
+func (t TrustLevel) String() string {
+	switch t {
+	case TrustLevelVerifiedChecksum:
+		return "verified checksum"
+	case TrustLevelSignedByKey:
+		return "signed"
+	case TrustLevelMatchingOfficialHashes:
+		return "matching official hashes"
+	default:
+		return "unverified"
+	}
+}
+
+// AuthenticationResult records what trust was actually established for a
+// downloaded file, so that a caller can tell a checksum match apart from a
+// verified signature rather than treating every non-noop result the same.
+type AuthenticationResult struct {
+	Trust TrustLevel
+	// KeyID is set when Trust is TrustLevelSignedByKey, to the fingerprint
+	// of the key that produced the signature.
+	KeyID string
+	// Checksum, if set, is the underlying checksum comparison that fed into
+	// this result.
+	Checksum *VerificationResult
+	// Warning holds a non-fatal caveat about the result, e.g. "signed by a
+	// key that is not the current release key".
+	Warning string
+}
+
+// IsAuthenticated returns true when some positive trust was established and
+// no checksum that was checked came back invalid.
+func (a AuthenticationResult) IsAuthenticated() bool {
+	if a.Checksum != nil && a.Checksum.IsInvalid() {
+		return false
+	}
+	return a.Trust != TrustLevelUnverified
+}
+
+// String renders a single-line summary of the trust established, e.g.
+// "signed by 0x0B588DFF0527A9B7, sha256 ok".
+func (a AuthenticationResult) String() string {
+	parts := make([]string, 0, 2)
+	if a.KeyID != "" {
+		parts = append(parts, fmt.Sprintf("signed by 0x%s", a.KeyID))
+	}
+	if a.Checksum != nil && len(a.Checksum.Valid) > 0 {
+		parts = append(parts, fmt.Sprintf("%s ok", strings.ToLower(englishJoin(a.Checksum.Valid, "/", "/"))))
+	}
+	if len(parts) == 0 {
+		return a.Trust.String()
+	}
+	message := strings.Join(parts, ", ")
+	if a.Warning != "" {
+		message += " (" + a.Warning + ")"
+	}
+	return message
+}
+
+// NewAuthenticationResultFromChecksum wraps a checksum VerificationResult as
+// an AuthenticationResult.
+func NewAuthenticationResultFromChecksum(checksum *VerificationResult) *AuthenticationResult {
+	auth := &AuthenticationResult{Checksum: checksum}
+	if checksum.IsValid() {
+		auth.Trust = TrustLevelVerifiedChecksum
+	}
+	return auth
+}
+
+// MergeAuthenticationResults combines multiple results, e.g. one from
+// checksum verification and one from a GPG signature, into a single result
+// reflecting the highest trust level achieved.
+func MergeAuthenticationResults(results ...*AuthenticationResult) *AuthenticationResult {
+	merged := &AuthenticationResult{}
+	for _, result := range results {
+		if result == nil {
+			continue
+		}
+		if result.Trust > merged.Trust {
+			merged.Trust = result.Trust
+		}
+		if result.KeyID != "" {
+			merged.KeyID = result.KeyID
+		}
+		if result.Checksum != nil {
+			merged.Checksum = result.Checksum
+		}
+		if result.Warning != "" {
+			merged.Warning = result.Warning
+		}
+	}
+	return merged
+}
@@ -0,0 +1,66 @@
+package dlverify
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthenticationResultIsAuthenticated(t *testing.T) {
+	t.Run("Unverified result is not authenticated", func(t *testing.T) {
+		result := AuthenticationResult{Trust: TrustLevelUnverified}
+		assert.False(t, result.IsAuthenticated())
+	})
+
+	t.Run("Signed result is authenticated", func(t *testing.T) {
+		result := AuthenticationResult{Trust: TrustLevelSignedByKey}
+		assert.True(t, result.IsAuthenticated())
+	})
+
+	t.Run("A trusted result with a failed checksum is not authenticated", func(t *testing.T) {
+		result := AuthenticationResult{
+			Trust:    TrustLevelSignedByKey,
+			Checksum: &VerificationResult{Invalid: []string{"SHA256"}},
+		}
+		assert.False(t, result.IsAuthenticated())
+	})
+}
+
+func TestAuthenticationResultString(t *testing.T) {
+	t.Run("Includes key id and valid checksums", func(t *testing.T) {
+		result := AuthenticationResult{
+			Trust:    TrustLevelSignedByKey,
+			KeyID:    "0B588DFF0527A9B7",
+			Checksum: &VerificationResult{Valid: []string{"SHA256"}},
+		}
+		assert.Equal(t, "signed by 0x0B588DFF0527A9B7, sha256 ok", result.String())
+	})
+
+	t.Run("Falls back to the trust level when nothing else was recorded", func(t *testing.T) {
+		result := AuthenticationResult{Trust: TrustLevelUnverified}
+		assert.Equal(t, "unverified", result.String())
+	})
+
+	t.Run("Appends a warning in parentheses", func(t *testing.T) {
+		result := AuthenticationResult{
+			Trust:   TrustLevelSignedByKey,
+			KeyID:   "0B588DFF0527A9B7",
+			Warning: "not the current release key",
+		}
+		assert.Equal(t, "signed by 0x0B588DFF0527A9B7 (not the current release key)", result.String())
+	})
+}
+
+func TestMergeAuthenticationResults(t *testing.T) {
+	t.Run("Keeps the highest trust level and ignores nils", func(t *testing.T) {
+		checksum := &VerificationResult{Valid: []string{"SHA256"}}
+		merged := MergeAuthenticationResults(
+			nil,
+			&AuthenticationResult{Trust: TrustLevelVerifiedChecksum, Checksum: checksum},
+			&AuthenticationResult{Trust: TrustLevelSignedByKey, KeyID: "ABCD"},
+		)
+		assert.Equal(t, TrustLevelSignedByKey, merged.Trust)
+		assert.Equal(t, "ABCD", merged.KeyID)
+		assert.Equal(t, checksum, merged.Checksum)
+	})
+}
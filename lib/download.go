@@ -0,0 +1,196 @@
+package dlverify
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// progressThrottle is the minimum time between progress updates delivered to
+// a Downloader's Progress callback.
+const progressThrottle = 250 * time.Millisecond
+
+// maxDownloadAttempts is how many attempts are made at a download, the
+// first included, retrying transient failures with exponential backoff,
+// before giving up.
+const maxDownloadAttempts = 5
+
+// ProgressUpdate describes how much of a download has completed so far.
+type ProgressUpdate struct {
+	BytesSoFar int64
+	// TotalBytes is 0 when the server did not report a Content-Length.
+	TotalBytes int64
+}
+
+// Downloader fetches files over HTTP(S), honoring context cancellation,
+// resuming a partially downloaded file where possible, and retrying
+// transient failures with exponential backoff. The zero value is ready to
+// use; http.DefaultTransport (and so HTTP_PROXY/HTTPS_PROXY) is used when
+// Client is nil.
+type Downloader struct {
+	// Client is the HTTP client used to perform requests.
+	Client *http.Client
+	// Progress, if set, receives throttled updates as the download
+	// proceeds.
+	Progress func(ProgressUpdate)
+	// Extra writers are tee'd the downloaded bytes as they are written to
+	// disk, e.g. a ChecksumConfig's NewMultiHasher. When a retry restarts
+	// the download from scratch (the server ignored the Range request and
+	// sent the whole body again), any Extra writer implementing Reset() is
+	// reset so it doesn't double-count the discarded partial attempt.
+	Extra []io.Writer
+}
+
+// resettable is implemented by hash.Hash, among others. Extra writers that
+// implement it are reset whenever a download restarts from scratch, so they
+// don't accumulate bytes from a discarded partial attempt.
+type resettable interface {
+	Reset()
+}
+
+// NewDownloader creates a Downloader with sane defaults.
+func NewDownloader() *Downloader {
+	return &Downloader{Client: &http.Client{}}
+}
+
+// Download fetches url into folder, resuming a previous partial download of
+// the same file if one is found on disk, and returns the local path to the
+// downloaded file.
+func (d *Downloader) Download(ctx context.Context, folder, url string) (string, error) {
+	client := d.Client
+	if client == nil {
+		client = &http.Client{}
+	}
+	localPath := path.Join(folder, path.Base(url))
+
+	var lastErr error
+	for attempt := 0; attempt < maxDownloadAttempts; attempt++ {
+		if attempt > 0 {
+			backoff := time.Duration(1<<uint(attempt)) * time.Second
+			log.WithFields(log.Fields{
+				"url": url, "attempt": attempt, "backoff": backoff,
+			}).Info("Retrying download after transient failure")
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return "", ctx.Err()
+			}
+		}
+		transient, err := d.downloadAttempt(ctx, client, url, localPath)
+		if err == nil {
+			return localPath, nil
+		}
+		if !transient {
+			return "", err
+		}
+		lastErr = err
+	}
+	return "", fmt.Errorf("download failed after %d attempts: %w", maxDownloadAttempts, lastErr)
+}
+
+// downloadAttempt performs a single download attempt. The returned bool is
+// true when the failure is transient and worth retrying.
+func (d *Downloader) downloadAttempt(ctx context.Context, client *http.Client, url, localPath string) (bool, error) {
+	var resumeFrom int64
+	if info, err := os.Stat(localPath); err == nil {
+		resumeFrom = info.Size()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return false, err
+	}
+	if resumeFrom > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", resumeFrom))
+	}
+
+	log.WithFields(log.Fields{
+		"url": url, "target": localPath, "resumeFrom": resumeFrom,
+	}).Debug("Starting download")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err": err, "url": url,
+		}).Warn("Transient error fetching URL")
+		return true, err
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_WRONLY | os.O_CREATE
+	switch resp.StatusCode {
+	case http.StatusOK:
+		flags |= os.O_TRUNC
+		resumeFrom = 0
+		for _, extra := range d.Extra {
+			if r, ok := extra.(resettable); ok {
+				r.Reset()
+			}
+		}
+	case http.StatusPartialContent:
+		flags |= os.O_APPEND
+	case http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true, fmt.Errorf("server returned transient status %d", resp.StatusCode)
+	default:
+		return false, fmt.Errorf("server returned unexpected status %d", resp.StatusCode)
+	}
+
+	out, err := os.OpenFile(localPath, flags, 0644)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err": err, "path": localPath,
+		}).Error("Failed to open file")
+		return false, err
+	}
+	defer out.Close()
+
+	total := int64(0)
+	if resp.ContentLength >= 0 {
+		total = resumeFrom + resp.ContentLength
+	}
+	reader := &progressTrackingReader{
+		r:        resp.Body,
+		onUpdate: d.Progress,
+		soFar:    resumeFrom,
+		total:    total,
+	}
+	writers := append([]io.Writer{out}, d.Extra...)
+	written, err := io.Copy(io.MultiWriter(writers...), reader)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err": err, "target": localPath,
+		}).Warn("Transient error reading response body")
+		return true, err
+	}
+	if resp.ContentLength >= 0 && written != resp.ContentLength {
+		return true, fmt.Errorf("expected %d bytes, got %d", resp.ContentLength, written)
+	}
+	return false, nil
+}
+
+// progressTrackingReader wraps an io.Reader, delivering throttled
+// ProgressUpdates to onUpdate as bytes are read through it.
+type progressTrackingReader struct {
+	r        io.Reader
+	onUpdate func(ProgressUpdate)
+	soFar    int64
+	total    int64
+	lastSent time.Time
+}
+
+func (p *progressTrackingReader) Read(buf []byte) (int, error) {
+	n, err := p.r.Read(buf)
+	p.soFar += int64(n)
+	if p.onUpdate != nil && (time.Since(p.lastSent) >= progressThrottle || err != nil) {
+		p.lastSent = time.Now()
+		p.onUpdate(ProgressUpdate{BytesSoFar: p.soFar, TotalBytes: p.total})
+	}
+	return n, err
+}
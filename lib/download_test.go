@@ -0,0 +1,67 @@
+package dlverify
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDownloaderResetsExtraWritersOnRestart reproduces a server that ignores
+// the Range header on retry and sends the whole file again from byte 0. The
+// downloaded file on disk is always correct, but before this was fixed, an
+// Extra writer (e.g. a running checksum) kept the bytes from the discarded
+// partial attempt, so it hashed more data than was ever saved to disk.
+func TestDownloaderResetsExtraWritersOnRestart(t *testing.T) {
+	full := []byte("the quick brown fox jumps over the lazy dog")
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			// Simulate a dropped connection partway through the response:
+			// announce the full length, write part of it, then hang up.
+			hj, ok := w.(http.Hijacker)
+			if !ok {
+				t.Fatal("test server does not support hijacking")
+			}
+			conn, bufrw, err := hj.Hijack()
+			assert.Nil(t, err)
+			defer conn.Close()
+			bufrw.WriteString("HTTP/1.1 200 OK\r\nContent-Length: " + strconv.Itoa(len(full)) + "\r\n\r\n")
+			bufrw.Write(full[:10])
+			bufrw.Flush()
+			return
+		}
+		// The retry: ignores any Range header and serves the whole body.
+		w.Header().Set("Content-Length", strconv.Itoa(len(full)))
+		w.WriteHeader(http.StatusOK)
+		w.Write(full)
+	}))
+	defer server.Close()
+
+	folder, err := ioutil.TempDir("", "dlverify-download-test")
+	assert.Nil(t, err)
+	defer os.RemoveAll(folder)
+
+	hasher := sha256.New()
+	downloader := NewDownloader()
+	downloader.Extra = []io.Writer{hasher}
+
+	localPath, err := downloader.Download(context.Background(), folder, server.URL+"/file.bin")
+	assert.Nil(t, err)
+
+	onDisk, err := ioutil.ReadFile(localPath)
+	assert.Nil(t, err)
+	assert.Equal(t, full, onDisk)
+
+	expected := sha256.Sum256(full)
+	assert.Equal(t, hex.EncodeToString(expected[:]), hex.EncodeToString(hasher.Sum(nil)))
+}
@@ -205,46 +205,65 @@ func (config ChecksumConfig) ValidateGivenChecksums() error {
 	return nil
 }
 
-// VerifyFileChecksums checks that the hashes for the given path is valid.
-func (config ChecksumConfig) VerifyFileChecksums(path string) (*VerificationResult, error) {
+// NewMultiHasher builds an io.Writer covering every hash configured, along
+// with a function that finalizes the hashes and compares them against the
+// expected values. This lets a caller tee an arbitrary reader (a download,
+// a decompression stream, ...) through every configured hash in a single
+// pass, rather than re-reading the same data once per hash.
+func (config ChecksumConfig) NewMultiHasher() (io.Writer, func() *VerificationResult) {
 	allChecksums := config.AsMap()
 	validTypes := ValidHashTypes()
-	result := new(VerificationResult)
-	for hashKey := range allChecksums {
-		expectedHash := allChecksums[hashKey]
-		hashImpl := validTypes[hashKey].New()
+	writers := make([]io.Writer, 0, len(allChecksums))
+	pairs := make([]sumPair, 0, len(allChecksums))
+	for hashKey, expectedHash := range allChecksums {
 		if expectedHash == "" {
 			continue
 		}
-
-		file, err := os.Open(path)
-		if err != nil {
-			log.WithFields(log.Fields{
-				"err": err, "path": path,
-			}).Error("Failed to Open path")
-			return nil, err
-		}
-		_, err = io.Copy(hashImpl, file)
-		if err != nil {
+		hashImpl := validTypes[hashKey].New()
+		writers = append(writers, hashImpl)
+		pairs = append(pairs, sumPair{Name: hashKey, Expected: expectedHash, Hash: hashImpl})
+	}
+	finish := func() *VerificationResult {
+		result := new(VerificationResult)
+		for _, pair := range pairs {
+			pathHash := hex.EncodeToString(pair.Hash.Sum(nil))
 			log.WithFields(log.Fields{
-				"err": err, "path": path, "method": hashKey,
-			}).Error("Failed to load date into Hash")
-			return nil, err
+				"method":  pair.Name,
+				"value":   pathHash,
+				"matches": pair.Expected == pathHash,
+			}).Info("Checksum Generated.")
+			if pair.Expected == pathHash {
+				result.Valid = append(result.Valid, pair.Name)
+			} else {
+				result.Invalid = append(result.Invalid, pair.Name)
+			}
 		}
-		pathHash := hex.EncodeToString(hashImpl.Sum(nil))
+		return result
+	}
+	return io.MultiWriter(writers...), finish
+}
+
+// VerifyFileChecksums checks that the hashes for the given path is valid,
+// reading the file exactly once regardless of how many hash types are
+// configured.
+func (config ChecksumConfig) VerifyFileChecksums(path string) (*AuthenticationResult, error) {
+	file, err := os.Open(path)
+	if err != nil {
 		log.WithFields(log.Fields{
-			"path":    path,
-			"method":  hashKey,
-			"value":   pathHash,
-			"matches": expectedHash == pathHash,
-		}).Info("Checksum Generated.")
-		if expectedHash == pathHash {
-			result.Valid = append(result.Valid, hashKey)
-		} else {
-			result.Invalid = append(result.Invalid, hashKey)
-		}
+			"err": err, "path": path,
+		}).Error("Failed to Open path")
+		return nil, err
+	}
+	defer file.Close()
+
+	writer, finish := config.NewMultiHasher()
+	if _, err := io.Copy(writer, file); err != nil {
+		log.WithFields(log.Fields{
+			"err": err, "path": path,
+		}).Error("Failed to load data into Hash")
+		return nil, err
 	}
-	return result, nil
+	return NewAuthenticationResultFromChecksum(finish()), nil
 }
 
 func hashToHexDecStr(hash []byte) string {
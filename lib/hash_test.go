@@ -0,0 +1,36 @@
+package dlverify
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// sha256 of "hello", per `printf 'hello' | sha256sum`.
+const helloSha256 = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+
+func TestChecksumConfigNewMultiHasher(t *testing.T) {
+	t.Run("Matches a correct hash and flags an incorrect one in the same pass", func(t *testing.T) {
+		config := ChecksumConfig{
+			Sha256: helloSha256,
+			Md5:    "deadbeefdeadbeefdeadbeefdeadbeef",
+		}
+		writer, finish := config.NewMultiHasher()
+		_, err := writer.Write([]byte("hello"))
+		assert.Nil(t, err)
+
+		result := finish()
+		assert.Contains(t, result.Valid, "SHA256")
+		assert.Contains(t, result.Invalid, "MD5")
+	})
+
+	t.Run("Is a no-op when no checksums are configured", func(t *testing.T) {
+		config := ChecksumConfig{}
+		writer, finish := config.NewMultiHasher()
+		_, err := writer.Write([]byte("hello"))
+		assert.Nil(t, err)
+
+		result := finish()
+		assert.True(t, result.IsNoOp())
+	})
+}
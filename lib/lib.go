@@ -2,6 +2,7 @@ package dlverify
 
 import (
 	"io"
+	"io/ioutil"
 	"net/http"
 	"os"
 	"path"
@@ -11,8 +12,29 @@ import (
 
 // ALL THE TODOS
 
-// DownloadToTemporaryFile the file
-func DownloadToTemporaryFile(folder, url string) (string, error) {
+// Download fetches url into a freshly created temporary directory, returning
+// the local path to the downloaded file. It is a convenience wrapper around
+// DownloadToTemporaryFile for callers that don't already manage a temporary
+// directory themselves. When verbose is true, the temporary directory is
+// logged at debug level.
+func Download(url string, verbose bool) (string, error) {
+	folder, err := ioutil.TempDir("", "dlverify")
+	if err != nil {
+		return "", err
+	}
+	if verbose {
+		log.WithFields(log.Fields{
+			"folder": folder,
+		}).Debug("Created temporary directory")
+	}
+	return DownloadToTemporaryFile(folder, url)
+}
+
+// DownloadToTemporaryFile downloads url into folder, tee-ing the response
+// body through any extra writers (e.g. a ChecksumConfig's NewMultiHasher) so
+// that verification can happen in the same pass as the download, rather
+// than re-reading the file from disk afterwards.
+func DownloadToTemporaryFile(folder, url string, extra ...io.Writer) (string, error) {
 	localPath := path.Join(folder, path.Base(url))
 
 	out, err := os.Create(localPath)
@@ -37,7 +59,8 @@ func DownloadToTemporaryFile(folder, url string) (string, error) {
 	}
 	defer resp.Body.Close()
 
-	_, err = io.Copy(out, resp.Body)
+	writers := append([]io.Writer{out}, extra...)
+	_, err = io.Copy(io.MultiWriter(writers...), resp.Body)
 	if err != nil {
 		log.WithFields(log.Fields{
 			"err": err, "target": localPath,